@@ -0,0 +1,122 @@
+package particlrpc
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+// ChainClient is the interface implemented by RPC clients for Particl and its
+// forks. ParticlRpc is the built-in implementation; downstream users can
+// register additional implementations for Particl-derived chains (e.g.
+// GhostCoin) via Register without forking this module.
+type ChainClient interface {
+	CallRpc(cmd string, wallet string, args []interface{}, res interface{}) error
+	CallRpcContext(ctx context.Context, cmd string, wallet string, args []interface{}, res interface{}) error
+	CallRpcBatch(calls []*RpcCall) error
+
+	GetNetworkInfo() (*NetworkInfo, error)
+	GetBlockchainInfo() (*BlockchainInfo, error)
+	GetBlock(hash string) (*Block, error)
+	GetStakingInfo(wallet string) (*StakingInfo, error)
+	GetUptime() (int64, error)
+	GetTxOut(txid string, vout int, includeMempool bool) (*TxOut, error)
+	ListUnspent(wallet string, minConf, maxConf int, addresses []string) ([]Unspent, error)
+	Snapshot(wallet string) (*NetworkInfo, *BlockchainInfo, *StakingInfo, int64, error)
+
+	SubscribeBlocks(ctx context.Context, zmqEndpoint string) (<-chan BlockNotification, error)
+	SubscribeTxs(ctx context.Context, zmqEndpoint string) (<-chan TxNotification, error)
+	PollNewBlocks(ctx context.Context, interval time.Duration) (<-chan BlockNotification, error)
+}
+
+// Config configures a ChainClient built via NewChainClient. Network identifies
+// which registered factory to use; it is also stamped onto the Config passed to
+// that factory so implementations backing more than one name can tell them apart.
+type Config struct {
+	Network string
+
+	DataDir string
+	RpcHost string
+	RpcPort int
+
+	RpcTLS                bool
+	RpcCACert             string
+	RpcClientCert         string
+	RpcClientKey          string
+	RpcInsecureSkipVerify bool
+}
+
+// chainRegistry holds the factories registered via Register, keyed by chain name.
+var chainRegistry = make(map[string]func(cfg Config) (ChainClient, error))
+
+// Register adds a factory for the chain identified by name to the registry, so it
+// can subsequently be constructed via NewChainClient(name, cfg). Register is
+// typically called from an init function; calling it twice for the same name
+// overwrites the earlier registration.
+func Register(name string, factory func(cfg Config) (ChainClient, error)) {
+	chainRegistry[name] = factory
+}
+
+// NewChainClient constructs the ChainClient registered under name, passing it cfg.
+func NewChainClient(name string, cfg Config) (ChainClient, error) {
+	factory, ok := chainRegistry[name]
+	if !ok {
+		return nil, errors.Errorf("particlrpc: no chain client registered for %q", name)
+	}
+
+	cfg.Network = name
+
+	return factory(cfg)
+}
+
+func init() {
+	Register("particl-mainnet", func(cfg Config) (ChainClient, error) { return newParticlRpcFromConfig(cfg, 51735) })
+	Register("particl-testnet", func(cfg Config) (ChainClient, error) { return newParticlRpcFromConfig(cfg, 51935) })
+	Register("particl-regtest", func(cfg Config) (ChainClient, error) { return newParticlRpcFromConfig(cfg, 11935) })
+}
+
+// newParticlRpcFromConfig builds a *ParticlRpc from cfg in a single validation
+// pass, applying the same host/port defaulting the individual setters use, and
+// returns an error only if the configured CA/client certificates fail to load.
+func newParticlRpcFromConfig(cfg Config, defaultPort int) (*ParticlRpc, error) {
+	rpc := new(ParticlRpc)
+
+	rpc.dataDir = cfg.DataDir
+	if rpc.dataDir == "" {
+		rpc.dataDir = "."
+	}
+
+	rpc.rpcHost = cfg.RpcHost
+	if rpc.rpcHost == "" {
+		rpc.rpcHost = "localhost"
+	}
+
+	rpc.defaultPort = defaultPort
+
+	rpc.rpcPort = cfg.RpcPort
+	if rpc.rpcPort <= 0 {
+		rpc.rpcPort = defaultPort
+	}
+
+	rpc.httpClient = &http.Client{}
+	rpc.rpcTLS = cfg.RpcTLS
+	rpc.rpcInsecureSkipVerify = cfg.RpcInsecureSkipVerify
+
+	if cfg.RpcCACert != "" {
+		if err := rpc.SetRpcCACert(cfg.RpcCACert); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.RpcClientCert != "" && cfg.RpcClientKey != "" {
+		if err := rpc.SetRpcClientCert(cfg.RpcClientCert, cfg.RpcClientKey); err != nil {
+			return nil, err
+		}
+	}
+
+	rpc.rebuildTransport()
+	rpc.rebuildURL()
+
+	return rpc, nil
+}
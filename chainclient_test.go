@@ -0,0 +1,63 @@
+package particlrpc
+
+import "testing"
+
+func TestNewChainClientDefaultPorts(t *testing.T) {
+	cases := []struct {
+		network  string
+		wantPort int
+	}{
+		{"particl-mainnet", 51735},
+		{"particl-testnet", 51935},
+		{"particl-regtest", 11935},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.network, func(t *testing.T) {
+			client, err := NewChainClient(tc.network, Config{})
+			if err != nil {
+				t.Fatalf("NewChainClient(%q) returned an error: %v", tc.network, err)
+			}
+
+			rpc, ok := client.(*ParticlRpc)
+			if !ok {
+				t.Fatalf("NewChainClient(%q) returned a %T, want *ParticlRpc", tc.network, client)
+			}
+
+			if rpc.rpcPort != tc.wantPort {
+				t.Errorf("rpcPort = %d, want %d", rpc.rpcPort, tc.wantPort)
+			}
+			if rpc.defaultPort != tc.wantPort {
+				t.Errorf("defaultPort = %d, want %d", rpc.defaultPort, tc.wantPort)
+			}
+
+			// SetRpcPort(0) must fall back to this network's default port, not
+			// mainnet's - this is the regression dcb50e4 patched by hand.
+			rpc.SetRpcPort(0)
+			if rpc.rpcPort != tc.wantPort {
+				t.Errorf("after SetRpcPort(0), rpcPort = %d, want %d", rpc.rpcPort, tc.wantPort)
+			}
+		})
+	}
+}
+
+func TestNewChainClientUnregisteredName(t *testing.T) {
+	if _, err := NewChainClient("not-a-real-chain", Config{}); err == nil {
+		t.Error("expected an error for an unregistered chain name")
+	}
+}
+
+func TestNewChainClientHonorsExplicitPort(t *testing.T) {
+	client, err := NewChainClient("particl-testnet", Config{RpcPort: 9999})
+	if err != nil {
+		t.Fatalf("NewChainClient returned an error: %v", err)
+	}
+
+	rpc := client.(*ParticlRpc)
+	if rpc.rpcPort != 9999 {
+		t.Errorf("rpcPort = %d, want 9999 (explicit Config.RpcPort must win over the default)", rpc.rpcPort)
+	}
+	if rpc.defaultPort != 51935 {
+		t.Errorf("defaultPort = %d, want 51935 (network default, unaffected by the explicit port)", rpc.defaultPort)
+	}
+}
@@ -1,20 +1,39 @@
 package particlrpc
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type ParticlRpc struct {
-	dataDir    string
-	rpcHost    string
-	rpcPort    int
-	rpcAuth    string
-	httpClient *http.Client
+	dataDir     string
+	rpcHost     string
+	rpcPort     int
+	defaultPort int
+	rpcAuth     string
+	httpClient  *http.Client
+	baseURL     string
+
+	rpcTLS                bool
+	rpcCAPool             *x509.CertPool
+	rpcClientCert         *tls.Certificate
+	rpcInsecureSkipVerify bool
+
+	rpcTimeout          time.Duration
+	rpcRetryMaxAttempts int
+	rpcRetryBackoff     time.Duration
+
+	zmqAutoFetchBlock bool
+
+	utxoCache *utxoCache
 }
 
 type Sat int64
@@ -124,14 +143,10 @@ type rpcResponse struct {
 
 // NewParticlRpc creates a new ParticlRpc instance with default settings:
 // dataDir: ".", rpcHost: "localhost", rpcPort: 51735
+// It is sugar over NewChainClient("particl-mainnet", Config{}).
 func NewParticlRpc() *ParticlRpc {
-	rpc := new(ParticlRpc)
-
-	rpc.dataDir = "."
-	rpc.rpcHost = "localhost"
-	rpc.rpcPort = 51735
-	rpc.httpClient = &http.Client{}
-
+	// Config{} carries no CA/client cert paths, so newParticlRpcFromConfig cannot fail here.
+	rpc, _ := newParticlRpcFromConfig(Config{}, 51735)
 	return rpc
 }
 
@@ -151,15 +166,22 @@ func (rpc *ParticlRpc) SetRpcHost(host string) {
 	} else {
 		rpc.rpcHost = "localhost"
 	}
+	rpc.rebuildURL()
 }
 
-// SetRpcPort sets the port to which the RPC call will connect. Value <= 0 will be interpreted as 51735.
+// SetRpcPort sets the port to which the RPC call will connect. Value <= 0 will be
+// interpreted as the client's network default port (51735 for mainnet, or the
+// default port of the network the client was constructed for via NewChainClient;
+// an explicit Config.RpcPort is not remembered as the default).
 func (rpc *ParticlRpc) SetRpcPort(port int) {
 	if port > 0 {
 		rpc.rpcPort = port
+	} else if rpc.defaultPort > 0 {
+		rpc.rpcPort = rpc.defaultPort
 	} else {
 		rpc.rpcPort = 51735
 	}
+	rpc.rebuildURL()
 }
 
 // ReadConfig reads a JSON config file defining data dir (data_dir), rpc host ("rpc_host") and
@@ -194,6 +216,8 @@ func (rpc *ParticlRpc) ReadConfig(filename string) error {
 		rpc.rpcPort = cfg.RpcPort
 	}
 
+	rpc.rebuildURL()
+
 	return nil
 }
 
@@ -207,6 +231,7 @@ func (rpc *ParticlRpc) ReadPartRpcCookie() error {
 	}
 
 	rpc.rpcAuth = strings.TrimSpace(string(data))
+	rpc.rebuildURL()
 
 	return nil
 }
@@ -214,47 +239,11 @@ func (rpc *ParticlRpc) ReadPartRpcCookie() error {
 // CallRpc executes rpc command <cmd> with arguments <args> at the particl daemon. Returned data is
 // written to <res>, which must be a pointer to a data structure matching the command. If rpc command
 // is wallet specific, the wallet name can be passed in <wallet>, otherwise an empty string must be passed.
+// CallRpc uses context.Background(), so it cannot be canceled by the caller; the
+// per-attempt timeout and retry policy set via SetRpcTimeout and SetRpcRetryPolicy
+// still apply. Use CallRpcContext to pass a cancelable context.
 func (rpc *ParticlRpc) CallRpc(cmd string, wallet string, args []interface{}, res interface{}) error {
-	data, err := json.Marshal(map[string]interface{}{
-		"method": cmd,
-		"id":     2,
-		"params": args,
-	})
-
-	if err != nil {
-		return errors.Wrap(err, "JSON Marshal failed")
-	}
-
-	url := fmt.Sprintf("http://%s@%s:%d", rpc.rpcAuth, rpc.rpcHost, rpc.rpcPort)
-	if wallet != "" {
-		url += "/wallet/" + wallet
-	}
-	resp, err := rpc.httpClient.Post(url, "application/json", strings.NewReader(string(data)))
-	if err != nil {
-		return errors.Wrap(err, "Post failed")
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return errors.Errorf("Bad response status: %s", resp.Status)
-	}
-
-	response := rpcResponse{}
-	response.Result = res
-
-	decoder := json.NewDecoder(resp.Body)
-
-	err = decoder.Decode(&response)
-	if err != nil {
-		return errors.Wrap(err, "partRpc: Decode JSON")
-	}
-
-	if response.Err != "" {
-		return errors.Errorf("RPC response error: %s", response.Err)
-	}
-
-	return nil
+	return rpc.CallRpcContext(context.Background(), cmd, wallet, args, res)
 }
 
 //GetNetworkInfo executes the "getnetworkinfo" command and returns results.
@@ -0,0 +1,155 @@
+package particlrpc
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+)
+
+// RpcCall describes a single call to be issued as part of a CallRpcBatch request.
+// Result must be a pointer to a data structure matching Cmd, exactly as the res
+// parameter of CallRpc. After CallRpcBatch returns, Err holds the per-call error
+// (if any) reported by particld for this specific call.
+type RpcCall struct {
+	Cmd    string
+	Wallet string
+	Args   []interface{}
+	Result interface{}
+	Err    error
+}
+
+type batchRequest struct {
+	Method string        `json:"method"`
+	Id     int           `json:"id"`
+	Params []interface{} `json:"params"`
+}
+
+type batchResponseItem struct {
+	Result json.RawMessage `json:"result"`
+	Err    string          `json:"error"`
+	Id     int             `json:"id"`
+}
+
+// CallRpcBatch executes a set of RPC calls as a single JSON-RPC 2.0 batch request,
+// dispatching each response back to the Result/Err fields of the matching *RpcCall
+// by id. Calls targeting different wallets are split into separate sub-batches,
+// since the wallet is encoded in the URL path rather than in the request body; the
+// overall ordering of calls is preserved across sub-batches when assigning ids.
+// Per-call RPC errors are reported via RpcCall.Err and do not abort the batch -
+// only transport/HTTP-level failures cause CallRpcBatch itself to return an error.
+func (rpc *ParticlRpc) CallRpcBatch(calls []*RpcCall) error {
+	type walletGroup struct {
+		wallet string
+		calls  []*RpcCall
+		ids    []int
+	}
+
+	groups := make(map[string]*walletGroup)
+	var order []string
+
+	for i, c := range calls {
+		g, ok := groups[c.Wallet]
+		if !ok {
+			g = &walletGroup{wallet: c.Wallet}
+			groups[c.Wallet] = g
+			order = append(order, c.Wallet)
+		}
+		g.calls = append(g.calls, c)
+		g.ids = append(g.ids, i)
+	}
+
+	for _, wallet := range order {
+		g := groups[wallet]
+		if err := rpc.callRpcBatch(g.wallet, g.calls, g.ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// callRpcBatch issues a single JSON-RPC 2.0 batch POST for calls that all share
+// the same wallet, using ids to tag each request so responses can be matched back
+// to the originating *RpcCall regardless of the order particld returns them in.
+// It goes through postWithRetry, so a batch call gets the same timeout/retry
+// handling as CallRpcContext instead of hanging forever on a stalled particld.
+func (rpc *ParticlRpc) callRpcBatch(wallet string, calls []*RpcCall, ids []int) error {
+	reqs := make([]batchRequest, len(calls))
+	byId := make(map[int]*RpcCall, len(calls))
+
+	for i, c := range calls {
+		reqs[i] = batchRequest{Method: c.Cmd, Id: ids[i], Params: c.Args}
+		byId[ids[i]] = c
+	}
+
+	data, err := json.Marshal(reqs)
+	if err != nil {
+		return errors.Wrap(err, "JSON Marshal failed")
+	}
+
+	url := rpc.baseURL
+	if wallet != "" {
+		url += "/wallet/" + wallet
+	}
+
+	body, err := rpc.postWithRetry(context.Background(), url, data)
+	if err != nil {
+		return err
+	}
+
+	var items []batchResponseItem
+
+	if err := json.Unmarshal(body, &items); err != nil {
+		return errors.Wrap(err, "partRpc: Decode JSON batch")
+	}
+
+	for _, item := range items {
+		c, ok := byId[item.Id]
+		if !ok {
+			continue
+		}
+
+		if item.Err != "" {
+			c.Err = errors.Errorf("RPC response error: %s", item.Err)
+			continue
+		}
+
+		if c.Result != nil {
+			if err := json.Unmarshal(item.Result, c.Result); err != nil {
+				c.Err = errors.Wrap(err, "partRpc: Decode JSON")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Snapshot fetches getnetworkinfo, getblockchaininfo, getstakinginfo and uptime via
+// CallRpcBatch instead of four separate HTTP round trips. With wallet == "" this is
+// a single batch request; with a non-empty wallet, getstakinginfo is sent to that
+// wallet's URL, so it goes out as a second sub-batch.
+func (rpc *ParticlRpc) Snapshot(wallet string) (*NetworkInfo, *BlockchainInfo, *StakingInfo, int64, error) {
+	var netInfo NetworkInfo
+	var chainInfo BlockchainInfo
+	var stakingInfo StakingInfo
+	var uptime int64
+
+	calls := []*RpcCall{
+		{Cmd: "getnetworkinfo", Result: &netInfo},
+		{Cmd: "getblockchaininfo", Result: &chainInfo},
+		{Cmd: "getstakinginfo", Wallet: wallet, Result: &stakingInfo},
+		{Cmd: "uptime", Result: &uptime},
+	}
+
+	if err := rpc.CallRpcBatch(calls); err != nil {
+		return nil, nil, nil, 0, errors.Wrap(err, "ParticlRpc: Snapshot failed")
+	}
+
+	for _, c := range calls {
+		if c.Err != nil {
+			return nil, nil, nil, 0, errors.Wrap(c.Err, "ParticlRpc: Snapshot failed")
+		}
+	}
+
+	return &netInfo, &chainInfo, &stakingInfo, uptime, nil
+}
@@ -0,0 +1,130 @@
+package particlrpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type batchTestRequest struct {
+	Method string        `json:"method"`
+	Id     int           `json:"id"`
+	Params []interface{} `json:"params"`
+}
+
+// newBatchTestServer replies to each JSON-RPC 2.0 batch request with one item per
+// incoming call: method "fail" yields a per-call error, anything else echoes the
+// method name back as the result. It also records the URL path of every request it
+// receives, so callers can assert on wallet-based sub-batch splitting.
+func newBatchTestServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var paths []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		var reqs []batchTestRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("test server: decoding batch request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		items := make([]batchResponseItem, 0, len(reqs))
+		for _, req := range reqs {
+			if req.Method == "fail" {
+				items = append(items, batchResponseItem{Id: req.Id, Err: "boom"})
+				continue
+			}
+
+			result, _ := json.Marshal(req.Method)
+			items = append(items, batchResponseItem{Id: req.Id, Result: result})
+		}
+
+		json.NewEncoder(w).Encode(items)
+	}))
+
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := make([]string, len(paths))
+		copy(out, paths)
+		return out
+	}
+}
+
+func TestCallRpcBatchWalletGroupingAndDispatch(t *testing.T) {
+	srv, paths := newBatchTestServer(t)
+	defer srv.Close()
+
+	rpc := newTestRpc(t, srv)
+
+	var rDefault, rAlice, rBob string
+
+	calls := []*RpcCall{
+		{Cmd: "cmd-default", Result: &rDefault},
+		{Cmd: "cmd-alice", Wallet: "alice", Result: &rAlice},
+		{Cmd: "fail", Wallet: "alice"},
+		{Cmd: "cmd-bob", Wallet: "bob", Result: &rBob},
+	}
+
+	if err := rpc.CallRpcBatch(calls); err != nil {
+		t.Fatalf("CallRpcBatch returned a top-level error: %v", err)
+	}
+
+	if rDefault != "cmd-default" {
+		t.Errorf("default wallet result = %q, want %q", rDefault, "cmd-default")
+	}
+	if rAlice != "cmd-alice" {
+		t.Errorf("alice result = %q, want %q", rAlice, "cmd-alice")
+	}
+	if rBob != "cmd-bob" {
+		t.Errorf("bob result = %q, want %q", rBob, "cmd-bob")
+	}
+	if calls[2].Err == nil {
+		t.Error("expected a per-call error for the \"fail\" command")
+	}
+	if calls[0].Err != nil || calls[1].Err != nil || calls[3].Err != nil {
+		t.Error("a per-call error on one call must not affect the others")
+	}
+
+	gotPaths := paths()
+	wantPaths := map[string]bool{"/": false, "/wallet/alice": false, "/wallet/bob": false}
+	for _, p := range gotPaths {
+		if _, ok := wantPaths[p]; !ok {
+			t.Errorf("unexpected request path %q", p)
+		}
+		wantPaths[p] = true
+	}
+	for p, seen := range wantPaths {
+		if !seen {
+			t.Errorf("expected a sub-batch request to path %q, got none", p)
+		}
+	}
+	if len(gotPaths) != 3 {
+		t.Errorf("expected exactly 3 sub-batch requests (one per wallet group), got %d: %v", len(gotPaths), gotPaths)
+	}
+}
+
+func TestCallRpcBatchTopLevelErrorOnTransportFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	rpc := newTestRpc(t, srv)
+	rpc.SetRpcRetryPolicy(1, 0)
+
+	var res string
+	calls := []*RpcCall{{Cmd: "whatever", Result: &res}}
+
+	if err := rpc.CallRpcBatch(calls); err == nil {
+		t.Fatal("expected a top-level error on transport failure")
+	}
+}
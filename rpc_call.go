@@ -0,0 +1,165 @@
+package particlrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// rpcNetworkError wraps transport-level failures (connection errors, timeouts,
+// HTTP 5xx/429 responses) that CallRpcContext's retry loop is allowed to retry.
+// A JSON-RPC "error" field is never wrapped in this type, since such errors are
+// deterministic and retrying them would just repeat the same failure.
+type rpcNetworkError struct {
+	err error
+}
+
+func (e *rpcNetworkError) Error() string { return e.err.Error() }
+func (e *rpcNetworkError) Unwrap() error { return e.err }
+
+func isRetryableRpcError(err error) bool {
+	_, ok := err.(*rpcNetworkError)
+	return ok
+}
+
+// SetRpcTimeout sets the per-attempt timeout applied to each RPC HTTP request. A
+// value <= 0 disables the timeout (the request can run as long as ctx allows).
+func (rpc *ParticlRpc) SetRpcTimeout(d time.Duration) {
+	rpc.rpcTimeout = d
+}
+
+// SetRpcRetryPolicy configures how CallRpc/CallRpcContext retry on transport
+// failures and HTTP 5xx/429 responses. maxAttempts <= 1 disables retrying.
+// backoff is the delay between attempts and is honored against ctx.Done().
+func (rpc *ParticlRpc) SetRpcRetryPolicy(maxAttempts int, backoff time.Duration) {
+	rpc.rpcRetryMaxAttempts = maxAttempts
+	rpc.rpcRetryBackoff = backoff
+}
+
+// CallRpcContext behaves like CallRpc but takes a context.Context, which is
+// honored both for canceling the in-flight HTTP request and for aborting the
+// retry backoff early. Retries only happen for network-level failures and HTTP
+// 5xx/429 responses; a JSON-RPC "error" field is deterministic and is returned
+// immediately without retrying.
+func (rpc *ParticlRpc) CallRpcContext(ctx context.Context, cmd string, wallet string, args []interface{}, res interface{}) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"method": cmd,
+		"id":     2,
+		"params": args,
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "JSON Marshal failed")
+	}
+
+	url := rpc.baseURL
+	if wallet != "" {
+		url += "/wallet/" + wallet
+	}
+
+	body, err := rpc.postWithRetry(ctx, url, data)
+	if err != nil {
+		return err
+	}
+
+	response := rpcResponse{}
+	response.Result = res
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return errors.Wrap(err, "partRpc: Decode JSON")
+	}
+
+	if response.Err != "" {
+		return errors.Errorf("RPC response error: %s", response.Err)
+	}
+
+	return nil
+}
+
+// postWithRetry POSTs data to url, retrying on transport-level failures and HTTP
+// 5xx/429 responses according to the configured retry policy (see
+// SetRpcRetryPolicy), and returns the raw response body on success. It is shared
+// by CallRpcContext and CallRpcBatch so both the single-call and batch paths get
+// the same timeout/retry/connection-pooling behavior.
+func (rpc *ParticlRpc) postWithRetry(ctx context.Context, url string, data []byte) ([]byte, error) {
+	maxAttempts := rpc.rpcRetryMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, errors.Wrap(ctx.Err(), "partRpc: context canceled during retry backoff")
+			case <-time.After(rpc.rpcRetryBackoff):
+			}
+		}
+
+		body, err := rpc.doPost(ctx, url, data)
+		if err == nil {
+			return body, nil
+		}
+
+		lastErr = err
+
+		if !isRetryableRpcError(err) {
+			return nil, err
+		}
+	}
+
+	return nil, lastErr
+}
+
+// doPost performs a single HTTP POST attempt and returns the raw response body.
+func (rpc *ParticlRpc) doPost(ctx context.Context, url string, data []byte) ([]byte, error) {
+	reqCtx := ctx
+
+	if rpc.rpcTimeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, rpc.rpcTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "partRpc: building request failed")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := rpc.httpClient.Do(req)
+	if err != nil {
+		return nil, &rpcNetworkError{err: errors.Wrap(err, "Post failed")}
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "partRpc: reading response body failed")
+	}
+
+	if resp.StatusCode != 200 {
+		httpErr := errors.Errorf("Bad response status: %s", resp.Status)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, &rpcNetworkError{err: httpErr}
+		}
+		return nil, httpErr
+	}
+
+	return body, nil
+}
+
+// rebuildURL precomputes the scheme/auth/host/port portion of the RPC URL, so the
+// hot path in CallRpcContext only needs to append the per-call wallet suffix. It
+// is called whenever the host, port, auth or TLS setting changes.
+func (rpc *ParticlRpc) rebuildURL() {
+	rpc.baseURL = fmt.Sprintf("%s://%s@%s:%d", rpc.rpcScheme(), rpc.rpcAuth, rpc.rpcHost, rpc.rpcPort)
+}
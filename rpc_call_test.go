@@ -0,0 +1,153 @@
+package particlrpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRpc(t *testing.T, srv *httptest.Server) *ParticlRpc {
+	t.Helper()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parsing test server URL: %v", err)
+	}
+
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatalf("splitting test server host/port: %v", err)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("parsing test server port: %v", err)
+	}
+
+	rpc := NewParticlRpc()
+	rpc.SetRpcHost(host)
+	rpc.SetRpcPort(port)
+
+	return rpc
+}
+
+func TestCallRpcContextRetryGating(t *testing.T) {
+	cases := []struct {
+		name         string
+		statuses     []int
+		bodies       []string
+		maxAttempts  int
+		wantErr      bool
+		wantAttempts int32
+	}{
+		{
+			name:         "succeeds after one transient 500",
+			statuses:     []int{500, 200},
+			bodies:       []string{``, `{"result":1,"error":"","id":2}`},
+			maxAttempts:  3,
+			wantErr:      false,
+			wantAttempts: 2,
+		},
+		{
+			name:         "retries a 502 and a 429 before succeeding",
+			statuses:     []int{502, 429, 200},
+			bodies:       []string{``, ``, `{"result":1,"error":"","id":2}`},
+			maxAttempts:  3,
+			wantErr:      false,
+			wantAttempts: 3,
+		},
+		{
+			name:         "does not retry a JSON-RPC error field",
+			statuses:     []int{200},
+			bodies:       []string{`{"result":null,"error":"already in chain","id":2}`},
+			maxAttempts:  3,
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "gives up after exhausting attempts on persistent 502",
+			statuses:     []int{502, 502, 502},
+			bodies:       []string{``, ``, ``},
+			maxAttempts:  3,
+			wantErr:      true,
+			wantAttempts: 3,
+		},
+		{
+			name:         "a 404 is not retried",
+			statuses:     []int{404},
+			bodies:       []string{``},
+			maxAttempts:  3,
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := int(atomic.AddInt32(&calls, 1)) - 1
+				if i >= len(tc.statuses) {
+					i = len(tc.statuses) - 1
+				}
+				w.WriteHeader(tc.statuses[i])
+				w.Write([]byte(tc.bodies[i]))
+			}))
+			defer srv.Close()
+
+			rpc := newTestRpc(t, srv)
+			rpc.SetRpcRetryPolicy(tc.maxAttempts, time.Millisecond)
+
+			var res int
+			err := rpc.CallRpcContext(context.Background(), "getuptime", "", nil, &res)
+
+			if tc.wantErr && err == nil {
+				t.Error("expected an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got := atomic.LoadInt32(&calls); got != tc.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.wantAttempts, got)
+			}
+		})
+	}
+}
+
+func TestCallRpcContextHonorsCancellation(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	rpc := newTestRpc(t, srv)
+	rpc.SetRpcRetryPolicy(10, 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	var res int
+	err := rpc.CallRpcContext(ctx, "getuptime", "", nil, &res)
+
+	if err == nil {
+		t.Fatal("expected an error due to context cancellation")
+	}
+	if got := atomic.LoadInt32(&calls); got >= 10 {
+		t.Errorf("expected cancellation to cut retries short, got %d attempts", got)
+	}
+}
@@ -0,0 +1,289 @@
+package particlrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/go-zeromq/zmq4"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// BlockNotification describes a new best block, as reported either by the ZMQ
+// "hashblock" topic or by PollNewBlocks. Sequence is the ZMQ sequence number (for
+// poll-based notifications, a counter starting at 1 and incremented per emitted
+// notification) and can be used to detect dropped messages. Hash
+// holds the block hash in internal (little-endian) byte order. Block is only
+// populated when auto-fetching is enabled via SetZmqAutoFetchBlock.
+type BlockNotification struct {
+	Topic    string
+	Sequence uint32
+	Hash     [32]byte
+	Block    *Block
+}
+
+// TxNotification describes a new mempool transaction, as reported by the ZMQ
+// "hashtx" topic. Sequence is the ZMQ sequence number and can be used to detect
+// dropped messages. Txid holds the transaction id in internal (little-endian)
+// byte order.
+type TxNotification struct {
+	Topic    string
+	Sequence uint32
+	Txid     [32]byte
+}
+
+// GetBlock executes the "getblock" command for the given block hash and returns
+// the decoded block header/metadata.
+func (rpc *ParticlRpc) GetBlock(hash string) (*Block, error) {
+	var res Block
+
+	err := rpc.CallRpc("getblock", "", []interface{}{hash}, &res)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "ParticlRpc: getblock failed")
+	}
+
+	return &res, nil
+}
+
+// getBlockHash executes the "getblockhash" command for the given height.
+func (rpc *ParticlRpc) getBlockHash(height int) (string, error) {
+	var res string
+
+	err := rpc.CallRpc("getblockhash", "", []interface{}{height}, &res)
+
+	if err != nil {
+		return "", errors.Wrap(err, "ParticlRpc: getblockhash failed")
+	}
+
+	return res, nil
+}
+
+// SetZmqAutoFetchBlock controls whether SubscribeBlocks and PollNewBlocks follow up
+// a new block notification with a "getblock" call to populate BlockNotification.Block.
+func (rpc *ParticlRpc) SetZmqAutoFetchBlock(enabled bool) {
+	rpc.zmqAutoFetchBlock = enabled
+}
+
+// reverseBytes returns a copy of b with its byte order reversed, which is needed
+// to convert the internal little-endian hash/txid representation particld
+// publishes over ZMQ into the big-endian hex representation used by RPC calls
+// such as getblock.
+func reverseBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, v := range b {
+		out[len(b)-1-i] = v
+	}
+	return out
+}
+
+// zmqFrames is a single multipart message received from a ZMQ PUB socket:
+// [topic, payload, sequence number].
+type zmqFrames struct {
+	frames [][]byte
+}
+
+// subscribeZMQ connects to endpoint as a ZMQ SUB socket, subscribes to topic, and
+// streams received multipart messages on the returned channel until ctx is
+// canceled, at which point the socket is closed and the channel closed.
+func (rpc *ParticlRpc) subscribeZMQ(ctx context.Context, endpoint, topic string) (<-chan zmqFrames, error) {
+	sock := zmq4.NewSub(ctx)
+
+	if err := sock.Dial(endpoint); err != nil {
+		return nil, errors.Wrapf(err, "particlrpc: failed to dial zmq endpoint %s", endpoint)
+	}
+
+	if err := sock.SetOption(zmq4.OptionSubscribe, topic); err != nil {
+		sock.Close()
+		return nil, errors.Wrapf(err, "particlrpc: failed to subscribe to zmq topic %s", topic)
+	}
+
+	out := make(chan zmqFrames)
+
+	go func() {
+		defer close(out)
+		defer sock.Close()
+
+		for {
+			msg, err := sock.Recv()
+			if err != nil {
+				return
+			}
+
+			select {
+			case out <- zmqFrames{frames: msg.Frames}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// SubscribeBlocks subscribes to the "hashblock" ZMQ topic published by particld
+// (when started with e.g. -zmqpubhashblock=tcp://127.0.0.1:28332) and emits a
+// BlockNotification for every new best block. The channel is closed when ctx is
+// canceled.
+func (rpc *ParticlRpc) SubscribeBlocks(ctx context.Context, zmqEndpoint string) (<-chan BlockNotification, error) {
+	raw, err := rpc.subscribeZMQ(ctx, zmqEndpoint, "hashblock")
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan BlockNotification)
+
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case msg, ok := <-raw:
+				if !ok {
+					return
+				}
+
+				notif := rpc.blockNotificationFromFrames(msg.frames)
+
+				select {
+				case out <- notif:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// SubscribeTxs subscribes to the "hashtx" ZMQ topic published by particld (when
+// started with e.g. -zmqpubhashtx=tcp://127.0.0.1:28332) and emits a
+// TxNotification for every new mempool transaction. The channel is closed when
+// ctx is canceled.
+func (rpc *ParticlRpc) SubscribeTxs(ctx context.Context, zmqEndpoint string) (<-chan TxNotification, error) {
+	raw, err := rpc.subscribeZMQ(ctx, zmqEndpoint, "hashtx")
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan TxNotification)
+
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case msg, ok := <-raw:
+				if !ok {
+					return
+				}
+
+				notif := TxNotification{Topic: "hashtx"}
+				if len(msg.frames) >= 2 {
+					copy(notif.Txid[:], msg.frames[1])
+				}
+				if len(msg.frames) >= 3 {
+					notif.Sequence = binary.LittleEndian.Uint32(msg.frames[2])
+				}
+
+				select {
+				case out <- notif:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// blockNotificationFromFrames decodes a raw ZMQ "hashblock" multipart message and,
+// if SetZmqAutoFetchBlock was enabled, follows up with a "getblock" RPC call.
+func (rpc *ParticlRpc) blockNotificationFromFrames(frames [][]byte) BlockNotification {
+	notif := BlockNotification{Topic: "hashblock"}
+
+	if len(frames) >= 2 {
+		copy(notif.Hash[:], frames[1])
+	}
+	if len(frames) >= 3 {
+		notif.Sequence = binary.LittleEndian.Uint32(frames[2])
+	}
+
+	if rpc.zmqAutoFetchBlock {
+		hash := hex.EncodeToString(reverseBytes(notif.Hash[:]))
+		if block, err := rpc.GetBlock(hash); err == nil {
+			notif.Block = block
+		}
+	}
+
+	return notif
+}
+
+// PollNewBlocks is a fallback for deployments without ZMQ configured: it polls
+// "getblockchaininfo" every interval and emits a BlockNotification on the returned
+// channel for every height increase since the last poll, so callers can use the
+// same notification shape regardless of transport. The channel is closed when ctx
+// is canceled.
+func (rpc *ParticlRpc) PollNewBlocks(ctx context.Context, interval time.Duration) (<-chan BlockNotification, error) {
+	out := make(chan BlockNotification)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		lastHeight := -1
+		var seq uint32
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				info, err := rpc.GetBlockchainInfo()
+				if err != nil {
+					continue
+				}
+
+				if lastHeight == -1 {
+					lastHeight = info.Blocks
+					continue
+				}
+
+				for h := lastHeight + 1; h <= info.Blocks; h++ {
+					seq++
+					notif := BlockNotification{Topic: "hashblock", Sequence: seq}
+
+					if hash, err := rpc.getBlockHash(h); err == nil {
+						if raw, err := hex.DecodeString(hash); err == nil {
+							copy(notif.Hash[:], reverseBytes(raw))
+						}
+
+						if rpc.zmqAutoFetchBlock {
+							if block, err := rpc.GetBlock(hash); err == nil {
+								notif.Block = block
+							}
+						}
+					}
+
+					select {
+					case out <- notif:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				lastHeight = info.Blocks
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,152 @@
+package particlrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newPollTestServer replies to "getblockchaininfo" with the next height in
+// heights (sticking on the last one once exhausted) and to "getblockhash" with a
+// deterministic 32 byte hash derived from the requested height.
+func newPollTestServer(t *testing.T, heights []int) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	call := 0
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("test server: decoding request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch req.Method {
+		case "getblockchaininfo":
+			mu.Lock()
+			h := heights[call]
+			if call < len(heights)-1 {
+				call++
+			}
+			mu.Unlock()
+			fmt.Fprintf(w, `{"result":{"blocks":%d},"error":"","id":2}`, h)
+		case "getblockhash":
+			height := int(req.Params[0].(float64))
+			fmt.Fprintf(w, `{"result":%q,"error":"","id":2}`, fmt.Sprintf("%064x", height))
+		default:
+			t.Errorf("test server: unexpected method %q", req.Method)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestPollNewBlocksEmitsOneNotificationPerNewHeight(t *testing.T) {
+	// First poll only establishes the starting height (no emission). The second
+	// poll advances two heights at once (6, 7) and must emit both, in order. The
+	// third poll reports the same height again and must emit nothing. The fourth
+	// poll advances once more (8, 9) and must pick up sequence numbering where it
+	// left off.
+	srv := newPollTestServer(t, []int{5, 7, 7, 9})
+	defer srv.Close()
+
+	rpc := newTestRpc(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := rpc.PollNewBlocks(ctx, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("PollNewBlocks returned an error: %v", err)
+	}
+
+	wantHeights := []int{6, 7, 8, 9}
+	var got []BlockNotification
+
+	for len(got) < len(wantHeights) {
+		select {
+		case n := <-ch:
+			got = append(got, n)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for notifications, got %d of %d", len(got), len(wantHeights))
+		}
+	}
+
+	for i, n := range got {
+		if n.Topic != "hashblock" {
+			t.Errorf("notification %d: Topic = %q, want %q", i, n.Topic, "hashblock")
+		}
+		if n.Sequence != uint32(i+1) {
+			t.Errorf("notification %d: Sequence = %d, want %d (sequence numbers must be contiguous and start at 1)", i, n.Sequence, i+1)
+		}
+
+		wantHash := fmt.Sprintf("%064x", wantHeights[i])
+		gotHash := hex.EncodeToString(reverseBytes(n.Hash[:]))
+		if gotHash != wantHash {
+			t.Errorf("notification %d: Hash (reversed) = %s, want %s", i, gotHash, wantHash)
+		}
+	}
+}
+
+func TestPollNewBlocksStopsOnContextCancel(t *testing.T) {
+	srv := newPollTestServer(t, []int{1})
+	defer srv.Close()
+
+	rpc := newTestRpc(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, err := rpc.PollNewBlocks(ctx, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("PollNewBlocks returned an error: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("expected the channel to be closed after context cancellation, got a notification instead")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the channel to close after context cancellation")
+	}
+}
+
+func TestBlockNotificationFromFramesDecodesHashAndSequence(t *testing.T) {
+	rpc := NewParticlRpc()
+
+	hash := make([]byte, 32)
+	for i := range hash {
+		hash[i] = byte(i)
+	}
+	seq := []byte{0x2a, 0x00, 0x00, 0x00}
+
+	notif := rpc.blockNotificationFromFrames([][]byte{[]byte("hashblock"), hash, seq})
+
+	var wantHash [32]byte
+	copy(wantHash[:], hash)
+
+	if notif.Topic != "hashblock" {
+		t.Errorf("Topic = %q, want %q", notif.Topic, "hashblock")
+	}
+	if notif.Sequence != 42 {
+		t.Errorf("Sequence = %d, want 42", notif.Sequence)
+	}
+	if notif.Hash != wantHash {
+		t.Error("Hash does not match the input frame")
+	}
+	if notif.Block != nil {
+		t.Error("Block should be nil when SetZmqAutoFetchBlock was never enabled")
+	}
+}
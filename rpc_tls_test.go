@@ -0,0 +1,136 @@
+package particlrpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// newTestTLSServer generates a self-signed cert via GenerateSelfSignedRpcCert for
+// "127.0.0.1", starts an httptest.Server using it, and returns the server along
+// with the path to the generated CA certificate.
+func newTestTLSServer(t *testing.T, handler http.Handler) (*httptest.Server, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "rpc.crt")
+	keyPath := filepath.Join(dir, "rpc.key")
+
+	if err := GenerateSelfSignedRpcCert(certPath, keyPath, []string{"127.0.0.1"}); err != nil {
+		t.Fatalf("GenerateSelfSignedRpcCert failed: %v", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("loading generated cert/key: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(handler)
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	srv.StartTLS()
+
+	return srv, certPath
+}
+
+func newTLSTestRpc(t *testing.T, srv *httptest.Server) *ParticlRpc {
+	t.Helper()
+
+	host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("splitting test server host/port: %v", err)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("parsing test server port: %v", err)
+	}
+
+	rpc := NewParticlRpc()
+	rpc.SetRpcHost(host)
+	rpc.SetRpcPort(port)
+	rpc.SetRpcTLS(true)
+
+	return rpc
+}
+
+func tlsOkHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":1,"error":"","id":2}`))
+	})
+}
+
+func TestGenerateSelfSignedRpcCertDialWithCACert(t *testing.T) {
+	srv, certPath := newTestTLSServer(t, tlsOkHandler())
+	defer srv.Close()
+
+	rpc := newTLSTestRpc(t, srv)
+
+	if err := rpc.SetRpcCACert(certPath); err != nil {
+		t.Fatalf("SetRpcCACert failed: %v", err)
+	}
+
+	var res int
+	if err := rpc.CallRpc("getuptime", "", nil, &res); err != nil {
+		t.Fatalf("CallRpc over TLS failed: %v", err)
+	}
+	if res != 1 {
+		t.Errorf("res = %d, want 1", res)
+	}
+}
+
+func TestGenerateSelfSignedRpcCertDialWithInsecureSkipVerify(t *testing.T) {
+	srv, _ := newTestTLSServer(t, tlsOkHandler())
+	defer srv.Close()
+
+	rpc := newTLSTestRpc(t, srv)
+	rpc.SetRpcInsecureSkipVerify(true)
+
+	var res int
+	if err := rpc.CallRpc("getuptime", "", nil, &res); err != nil {
+		t.Fatalf("CallRpc over TLS with InsecureSkipVerify failed: %v", err)
+	}
+}
+
+func TestTLSDialFailsWithoutTrustedCA(t *testing.T) {
+	srv, _ := newTestTLSServer(t, tlsOkHandler())
+	defer srv.Close()
+
+	rpc := newTLSTestRpc(t, srv)
+
+	var res int
+	if err := rpc.CallRpc("getuptime", "", nil, &res); err == nil {
+		t.Fatal("expected a TLS verification error when neither SetRpcCACert nor SetRpcInsecureSkipVerify was used")
+	}
+}
+
+func TestGenerateSelfSignedRpcCertIncludesRequestedHosts(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "rpc.crt")
+	keyPath := filepath.Join(dir, "rpc.key")
+
+	if err := GenerateSelfSignedRpcCert(certPath, keyPath, []string{"rpc.example.com", "127.0.0.1"}); err != nil {
+		t.Fatalf("GenerateSelfSignedRpcCert failed: %v", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("loading generated cert/key: %v", err)
+	}
+
+	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("parsing generated certificate: %v", err)
+	}
+
+	if err := x509Cert.VerifyHostname("rpc.example.com"); err != nil {
+		t.Errorf("expected certificate to cover hostname rpc.example.com: %v", err)
+	}
+	if err := x509Cert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Errorf("expected certificate to cover IP 127.0.0.1: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package particlrpc
+
+import (
+	"container/list"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// OutPoint identifies a transaction output.
+type OutPoint struct {
+	Txid string
+	Vout int
+}
+
+// TxOut is the result of a "gettxout" call. Value is a decimal coin amount (not
+// satoshis), matching the Bitcoin Core RPC convention particld follows.
+type TxOut struct {
+	Value         float64      `json:"value"`
+	ScriptPubKey  ScriptPubKey `json:"scriptPubKey"`
+	Confirmations int          `json:"confirmations"`
+	Coinbase      bool         `json:"coinbase"`
+}
+
+// Unspent is a single entry returned by "listunspent". Amount is a decimal coin
+// amount (not satoshis), matching the Bitcoin Core RPC convention particld
+// follows.
+type Unspent struct {
+	Txid          string  `json:"txid"`
+	Vout          int     `json:"vout"`
+	Address       string  `json:"address"`
+	ScriptPubKey  string  `json:"scriptPubKey"`
+	Amount        float64 `json:"amount"`
+	Confirmations int     `json:"confirmations"`
+	Spendable     bool    `json:"spendable"`
+	Solvable      bool    `json:"solvable"`
+}
+
+// GetTxOut executes the "gettxout" command for the given outpoint. If a UtxoCache
+// was enabled via SetUtxoCache, it is consulted first and populated on miss.
+// particld reports a spent or non-existent output as a null result; GetTxOut
+// surfaces that as (nil, nil) rather than a zero-value TxOut, and does not cache
+// it as a hit.
+func (rpc *ParticlRpc) GetTxOut(txid string, vout int, includeMempool bool) (*TxOut, error) {
+	key := utxoCacheKey{op: OutPoint{Txid: txid, Vout: vout}, includeMempool: includeMempool}
+
+	if rpc.utxoCache != nil {
+		if out, ok := rpc.utxoCache.get(key); ok {
+			return out, nil
+		}
+	}
+
+	var raw json.RawMessage
+
+	err := rpc.CallRpc("gettxout", "", []interface{}{txid, vout, includeMempool}, &raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParticlRpc: gettxout failed")
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var res TxOut
+
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, errors.Wrap(err, "ParticlRpc: gettxout failed")
+	}
+
+	if rpc.utxoCache != nil {
+		rpc.utxoCache.put(key, &res)
+	}
+
+	return &res, nil
+}
+
+// ListUnspent executes the "listunspent" command for wallet, filtered by
+// confirmation range and, if non-empty, the given addresses.
+func (rpc *ParticlRpc) ListUnspent(wallet string, minConf, maxConf int, addresses []string) ([]Unspent, error) {
+	if addresses == nil {
+		addresses = []string{}
+	}
+
+	var res []Unspent
+
+	err := rpc.CallRpc("listunspent", wallet, []interface{}{minConf, maxConf, addresses}, &res)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParticlRpc: listunspent failed")
+	}
+
+	return res, nil
+}
+
+// SetUtxoCache enables an in-process LRU cache of up to maxEntries GetTxOut
+// results, keyed by OutPoint and includeMempool. Passing maxEntries <= 0 disables
+// the cache.
+func (rpc *ParticlRpc) SetUtxoCache(maxEntries int) {
+	if maxEntries <= 0 {
+		rpc.utxoCache = nil
+		return
+	}
+
+	rpc.utxoCache = newUtxoCache(maxEntries)
+}
+
+// InvalidateOutPoint evicts op from the UtxoCache, if one is enabled. Callers
+// should call this once they learn, e.g. via the notification subsystem, that an
+// output has been spent.
+func (rpc *ParticlRpc) InvalidateOutPoint(op OutPoint) {
+	if rpc.utxoCache != nil {
+		rpc.utxoCache.invalidate(op)
+	}
+}
+
+// utxoCacheKey distinguishes cache entries by includeMempool as well as
+// OutPoint: a gettxout result fetched with includeMempool=true reflects
+// mempool-spent/0-conf state and must not be handed back for a subsequent
+// includeMempool=false lookup on the same outpoint, or vice versa.
+type utxoCacheKey struct {
+	op             OutPoint
+	includeMempool bool
+}
+
+// utxoCache is a fixed-size, thread-safe LRU cache of TxOut results keyed by
+// utxoCacheKey, following the per-outpoint cache design btcd moved to.
+type utxoCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[utxoCacheKey]*list.Element
+}
+
+type utxoCacheEntry struct {
+	key utxoCacheKey
+	out *TxOut
+}
+
+func newUtxoCache(maxEntries int) *utxoCache {
+	return &utxoCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[utxoCacheKey]*list.Element),
+	}
+}
+
+func (c *utxoCache) get(key utxoCacheKey) (*TxOut, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return el.Value.(*utxoCacheEntry).out, true
+}
+
+func (c *utxoCache) put(key utxoCacheKey, out *TxOut) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*utxoCacheEntry).out = out
+		return
+	}
+
+	el := c.ll.PushFront(&utxoCacheEntry{key: key, out: out})
+	c.items[key] = el
+
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*utxoCacheEntry).key)
+		}
+	}
+}
+
+// invalidate evicts both the includeMempool=true and includeMempool=false
+// entries for op, since InvalidateOutPoint's callers only know that an
+// outpoint was spent, not which includeMempool variant(s) are cached for it.
+func (c *utxoCache) invalidate(op OutPoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, includeMempool := range [...]bool{false, true} {
+		key := utxoCacheKey{op: op, includeMempool: includeMempool}
+		if el, ok := c.items[key]; ok {
+			c.ll.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
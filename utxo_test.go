@@ -0,0 +1,186 @@
+package particlrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUtxoCacheEviction(t *testing.T) {
+	cases := []struct {
+		name        string
+		maxEntries  int
+		puts        []string
+		wantEvicted []string
+		wantPresent []string
+	}{
+		{
+			name:        "capacity 1 keeps only the latest entry",
+			maxEntries:  1,
+			puts:        []string{"a", "b"},
+			wantEvicted: []string{"a"},
+			wantPresent: []string{"b"},
+		},
+		{
+			name:        "capacity 2 evicts the oldest on overflow",
+			maxEntries:  2,
+			puts:        []string{"a", "b", "c"},
+			wantEvicted: []string{"a"},
+			wantPresent: []string{"b", "c"},
+		},
+		{
+			name:        "within capacity nothing is evicted",
+			maxEntries:  3,
+			puts:        []string{"a", "b", "c"},
+			wantEvicted: nil,
+			wantPresent: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newUtxoCache(tc.maxEntries)
+
+			for i, txid := range tc.puts {
+				c.put(utxoCacheKey{op: OutPoint{Txid: txid}}, &TxOut{Confirmations: i})
+			}
+
+			for _, txid := range tc.wantEvicted {
+				if _, ok := c.get(utxoCacheKey{op: OutPoint{Txid: txid}}); ok {
+					t.Errorf("expected %q to have been evicted", txid)
+				}
+			}
+
+			for _, txid := range tc.wantPresent {
+				if _, ok := c.get(utxoCacheKey{op: OutPoint{Txid: txid}}); !ok {
+					t.Errorf("expected %q to still be cached", txid)
+				}
+			}
+		})
+	}
+}
+
+func TestUtxoCacheGetPromotesToFront(t *testing.T) {
+	c := newUtxoCache(2)
+
+	keyA := utxoCacheKey{op: OutPoint{Txid: "a"}}
+	keyB := utxoCacheKey{op: OutPoint{Txid: "b"}}
+	keyC := utxoCacheKey{op: OutPoint{Txid: "c"}}
+
+	c.put(keyA, &TxOut{})
+	c.put(keyB, &TxOut{})
+
+	// Touching keyA makes keyB the least recently used entry.
+	if _, ok := c.get(keyA); !ok {
+		t.Fatal("expected keyA to be cached")
+	}
+
+	c.put(keyC, &TxOut{})
+
+	if _, ok := c.get(keyB); ok {
+		t.Error("expected keyB to have been evicted instead of keyA")
+	}
+	if _, ok := c.get(keyA); !ok {
+		t.Error("expected keyA to still be cached after being touched")
+	}
+}
+
+func TestUtxoCacheInvalidate(t *testing.T) {
+	c := newUtxoCache(4)
+	op := OutPoint{Txid: "a"}
+	key := utxoCacheKey{op: op}
+
+	c.put(key, &TxOut{Confirmations: 3})
+
+	if _, ok := c.get(key); !ok {
+		t.Fatal("expected entry to be cached")
+	}
+
+	c.invalidate(op)
+
+	if _, ok := c.get(key); ok {
+		t.Error("expected entry to be gone after InvalidateOutPoint")
+	}
+
+	// Invalidating an absent entry must be a no-op, not a panic.
+	c.invalidate(OutPoint{Txid: "never-cached"})
+}
+
+func TestUtxoCacheIncludeMempoolVariantsAreDistinct(t *testing.T) {
+	c := newUtxoCache(4)
+	op := OutPoint{Txid: "a"}
+
+	withMempool := utxoCacheKey{op: op, includeMempool: true}
+	withoutMempool := utxoCacheKey{op: op, includeMempool: false}
+
+	c.put(withMempool, &TxOut{Confirmations: 0})
+
+	if _, ok := c.get(withoutMempool); ok {
+		t.Error("an includeMempool=true entry must not satisfy an includeMempool=false lookup")
+	}
+
+	c.put(withoutMempool, &TxOut{Confirmations: 5})
+
+	if out, ok := c.get(withMempool); !ok || out.Confirmations != 0 {
+		t.Error("the includeMempool=true entry must survive the includeMempool=false put for the same outpoint")
+	}
+
+	// InvalidateOutPoint (keyed only on OutPoint) must evict both variants.
+	c.invalidate(op)
+
+	if _, ok := c.get(withMempool); ok {
+		t.Error("expected the includeMempool=true entry to be invalidated")
+	}
+	if _, ok := c.get(withoutMempool); ok {
+		t.Error("expected the includeMempool=false entry to be invalidated")
+	}
+}
+
+// TestGetTxOutDecodesDecimalValue exercises the real gettxout JSON wire format,
+// where value is a decimal coin amount (e.g. 0.00031900) rather than an integer
+// number of satoshis.
+func TestGetTxOutDecodesDecimalValue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":{"value":0.00031900,"scriptPubKey":{"asm":"","hex":"","type":"pubkeyhash"},"confirmations":6,"coinbase":false},"error":null,"id":1}`))
+	}))
+	defer srv.Close()
+
+	rpc := newTestRpc(t, srv)
+
+	out, err := rpc.GetTxOut("deadbeef", 0, false)
+	if err != nil {
+		t.Fatalf("GetTxOut returned an error: %v", err)
+	}
+	if out == nil {
+		t.Fatal("expected a non-nil TxOut")
+	}
+	if out.Value != 0.00031900 {
+		t.Errorf("Value = %v, want 0.00031900", out.Value)
+	}
+	if out.Confirmations != 6 {
+		t.Errorf("Confirmations = %d, want 6", out.Confirmations)
+	}
+}
+
+// TestListUnspentDecodesDecimalAmount exercises the real listunspent JSON wire
+// format, where amount is a decimal coin amount rather than an integer number of
+// satoshis.
+func TestListUnspentDecodesDecimalAmount(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[{"txid":"deadbeef","vout":0,"address":"p123","scriptPubKey":"abc","amount":1.23456789,"confirmations":10,"spendable":true,"solvable":true}],"error":null,"id":1}`))
+	}))
+	defer srv.Close()
+
+	rpc := newTestRpc(t, srv)
+
+	unspent, err := rpc.ListUnspent("", 1, 9999999, nil)
+	if err != nil {
+		t.Fatalf("ListUnspent returned an error: %v", err)
+	}
+	if len(unspent) != 1 {
+		t.Fatalf("expected 1 unspent entry, got %d", len(unspent))
+	}
+	if unspent[0].Amount != 1.23456789 {
+		t.Errorf("Amount = %v, want 1.23456789", unspent[0].Amount)
+	}
+}